@@ -0,0 +1,128 @@
+// Package resources implements the CloudFormation custom resources Sparta
+// provisions to cover AWS APIs with no native CloudFormation resource type.
+package resources
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/codecommit"
+	gocf "github.com/mweagle/go-cloudformation"
+)
+
+// CodeCommitTriggerResourceType is the CloudFormation custom resource type
+// name used to provision CodeCommit repository triggers.
+const CodeCommitTriggerResourceType = "Custom::SpartaCodeCommitTrigger"
+
+// CodeCommitTriggerResource is a CloudFormation custom resource that
+// installs or removes a RepositoryTriggers entry on a CodeCommit repository
+// via PutRepositoryTriggers, since CloudFormation has no native
+// AWS::CodeCommit::Trigger resource type.
+type CodeCommitTriggerResource struct {
+	gocf.CloudFormationCustomResource
+	RepositoryArn *gocf.StringExpr
+	Branches      []string
+	Events        []string
+	LambdaTarget  *gocf.StringExpr
+}
+
+// CfnResourceType implements gocf.ResourceProperties, identifying this as a
+// custom resource backed by the Sparta custom resource Lambda.
+func (res *CodeCommitTriggerResource) CfnResourceType() string {
+	return CodeCommitTriggerResourceType
+}
+
+// IAMPrivileges returns the IAM actions the custom resource Lambda's
+// execution role needs in order to service this resource.
+func (res *CodeCommitTriggerResource) IAMPrivileges() []string {
+	return []string{
+		"codecommit:GetRepositoryTriggers",
+		"codecommit:PutRepositoryTriggers",
+	}
+}
+
+// triggerName derives the stable RepositoryTrigger name this custom
+// resource manages, so repeated Update calls replace rather than duplicate
+// the entry.
+func triggerName(physicalResourceID string) string {
+	return fmt.Sprintf("sparta-%s", physicalResourceID)
+}
+
+// Create installs this resource's RepositoryTriggers entry on
+// RepositoryArn's repository, preserving any triggers already present.
+func (res *CodeCommitTriggerResource) Create(awsSession *session.Session,
+	repositoryName string,
+	lambdaArn string,
+	physicalResourceID string) error {
+	return res.putTrigger(awsSession, repositoryName, lambdaArn, physicalResourceID)
+}
+
+// Update replaces this resource's previously installed RepositoryTriggers
+// entry with one reflecting the current Branches/Events.
+func (res *CodeCommitTriggerResource) Update(awsSession *session.Session,
+	repositoryName string,
+	lambdaArn string,
+	physicalResourceID string) error {
+	return res.putTrigger(awsSession, repositoryName, lambdaArn, physicalResourceID)
+}
+
+// Delete removes this resource's RepositoryTriggers entry from
+// RepositoryArn's repository, leaving any other triggers untouched.
+func (res *CodeCommitTriggerResource) Delete(awsSession *session.Session,
+	repositoryName string,
+	physicalResourceID string) error {
+	client := codecommit.New(awsSession)
+	existing, describeErr := client.GetRepositoryTriggers(&codecommit.GetRepositoryTriggersInput{
+		RepositoryName: aws.String(repositoryName),
+	})
+	if describeErr != nil {
+		return describeErr
+	}
+	name := triggerName(physicalResourceID)
+	remaining := make([]*codecommit.RepositoryTrigger, 0, len(existing.Triggers))
+	for _, eachTrigger := range existing.Triggers {
+		if aws.StringValue(eachTrigger.Name) != name {
+			remaining = append(remaining, eachTrigger)
+		}
+	}
+	_, putErr := client.PutRepositoryTriggers(&codecommit.PutRepositoryTriggersInput{
+		RepositoryName: aws.String(repositoryName),
+		Triggers:       remaining,
+	})
+	return putErr
+}
+
+// putTrigger upserts this resource's RepositoryTriggers entry alongside any
+// other triggers already configured on the repository.
+func (res *CodeCommitTriggerResource) putTrigger(awsSession *session.Session,
+	repositoryName string,
+	lambdaArn string,
+	physicalResourceID string) error {
+	client := codecommit.New(awsSession)
+	existing, describeErr := client.GetRepositoryTriggers(&codecommit.GetRepositoryTriggersInput{
+		RepositoryName: aws.String(repositoryName),
+	})
+	if describeErr != nil {
+		return describeErr
+	}
+	name := triggerName(physicalResourceID)
+	triggers := make([]*codecommit.RepositoryTrigger, 0, len(existing.Triggers)+1)
+	for _, eachTrigger := range existing.Triggers {
+		if aws.StringValue(eachTrigger.Name) != name {
+			triggers = append(triggers, eachTrigger)
+		}
+	}
+	triggers = append(triggers, &codecommit.RepositoryTrigger{
+		Name:           aws.String(name),
+		DestinationArn: aws.String(lambdaArn),
+		Branches:       aws.StringSlice(res.Branches),
+		Events:         aws.StringSlice(res.Events),
+		CustomData:     aws.String(physicalResourceID),
+	})
+	_, putErr := client.PutRepositoryTriggers(&codecommit.PutRepositoryTriggersInput{
+		RepositoryName: aws.String(repositoryName),
+		Triggers:       triggers,
+	})
+	return putErr
+}
@@ -0,0 +1,78 @@
+package step
+
+// ChoiceBranch represents a single Choices entry in a ChoiceState: a
+// variable comparison paired with the state to transition to when it
+// evaluates true.
+type ChoiceBranch struct {
+	Variable   string
+	Comparison string
+	Value      interface{}
+	Next       State
+}
+
+func (cb *ChoiceBranch) marshalASL() map[string]interface{} {
+	return map[string]interface{}{
+		"Variable":    cb.Variable,
+		cb.Comparison: cb.Value,
+		"Next":        cb.Next.Name(),
+	}
+}
+
+// ChoiceState is an ASL Choice state that branches based on the value of
+// one or more JSONPath expressions in the current input.
+type ChoiceState struct {
+	baseState
+	choices []*ChoiceBranch
+	deflt   State
+}
+
+// NewChoiceState returns an empty ChoiceState with the given name.
+func NewChoiceState(name string) *ChoiceState {
+	return &ChoiceState{baseState: baseState{name: name}}
+}
+
+// WithChoice appends a ChoiceBranch and returns the ChoiceState to support
+// fluent chaining.
+func (cs *ChoiceState) WithChoice(choice *ChoiceBranch) *ChoiceState {
+	cs.choices = append(cs.choices, choice)
+	return cs
+}
+
+// WithDefault sets the state to transition to when no Choices entry
+// matches and returns the ChoiceState to support fluent chaining.
+func (cs *ChoiceState) WithDefault(defaultState State) *ChoiceState {
+	cs.deflt = defaultState
+	return cs
+}
+
+// successors overrides baseState's linear successor with every state this
+// Choice can branch to: each Choices entry's Next plus Default.
+func (cs *ChoiceState) successors() []State {
+	successors := make([]State, 0, len(cs.choices)+1)
+	for _, eachChoice := range cs.choices {
+		successors = append(successors, eachChoice.Next)
+	}
+	if cs.deflt != nil {
+		successors = append(successors, cs.deflt)
+	}
+	return successors
+}
+
+// MarshalASL implements State.
+func (cs *ChoiceState) MarshalASL() (map[string]interface{}, error) {
+	choices := make([]map[string]interface{}, len(cs.choices))
+	for index, eachChoice := range cs.choices {
+		choices[index] = eachChoice.marshalASL()
+	}
+	entry := map[string]interface{}{
+		"Type":    "Choice",
+		"Choices": choices,
+	}
+	if cs.comment != "" {
+		entry["Comment"] = cs.comment
+	}
+	if cs.deflt != nil {
+		entry["Default"] = cs.deflt.Name()
+	}
+	return entry, nil
+}
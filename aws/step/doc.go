@@ -0,0 +1,7 @@
+// Package step provides a fluent builder for Amazon States Language (ASL)
+// state machines
+// (https://docs.aws.amazon.com/step-functions/latest/dg/concepts-amazon-states-language.html)
+// that integrate with sparta.LambdaAWSInfo instances so that Task states can
+// reference Lambda functions defined elsewhere in a Sparta service without
+// hardcoding ARNs.
+package step
@@ -0,0 +1,93 @@
+package step
+
+// Branch is an independent sequence of states executed concurrently by a
+// ParallelState. States is ordered; the first entry is the branch's start
+// state.
+type Branch struct {
+	States []State
+}
+
+// ParallelState is an ASL Parallel state that executes one or more Branches
+// concurrently and collects their outputs into an array.
+type ParallelState struct {
+	baseState
+	branches []*Branch
+	retriers []*TaskRetry
+}
+
+// NewParallelState returns an empty ParallelState with the given name.
+func NewParallelState(name string) *ParallelState {
+	return &ParallelState{baseState: baseState{name: name}}
+}
+
+// WithBranch appends a Branch and returns the ParallelState to support
+// fluent chaining.
+func (ps *ParallelState) WithBranch(branch *Branch) *ParallelState {
+	ps.branches = append(ps.branches, branch)
+	return ps
+}
+
+// WithRetriers appends one or more TaskRetry entries to the Parallel state's
+// Retry list and returns the ParallelState to support fluent chaining.
+func (ps *ParallelState) WithRetriers(retriers ...*TaskRetry) *ParallelState {
+	ps.retriers = append(ps.retriers, retriers...)
+	return ps
+}
+
+// WithNext sets the state to transition to once every branch completes and
+// returns the ParallelState to support fluent chaining.
+func (ps *ParallelState) WithNext(next State) *ParallelState {
+	ps.baseState.nextState = next
+	return ps
+}
+
+// WithEnd marks this ParallelState as a terminal state of the state
+// machine.
+func (ps *ParallelState) WithEnd() *ParallelState {
+	ps.baseState.end = true
+	return ps
+}
+
+// MarshalASL implements State.
+func (ps *ParallelState) MarshalASL() (map[string]interface{}, error) {
+	entry := ps.baseState.marshalTransition("Parallel")
+	branches := make([]map[string]interface{}, len(ps.branches))
+	for index, eachBranch := range ps.branches {
+		branchDef, branchErr := marshalBranch(eachBranch)
+		if branchErr != nil {
+			return nil, branchErr
+		}
+		branches[index] = branchDef
+	}
+	entry["Branches"] = branches
+	if len(ps.retriers) != 0 {
+		retry := make([]map[string]interface{}, len(ps.retriers))
+		for index, eachRetrier := range ps.retriers {
+			retry[index] = eachRetrier.marshalASL()
+		}
+		entry["Retry"] = retry
+	}
+	return entry, nil
+}
+
+// marshalBranch serializes a Branch's states into the nested
+// StartAt/States ASL document expected inside a Parallel state's Branches
+// list.
+func marshalBranch(branch *Branch) (map[string]interface{}, error) {
+	states := map[string]interface{}{}
+	for _, eachState := range branch.States {
+		stateDef, stateErr := eachState.MarshalASL()
+		if stateErr != nil {
+			return nil, stateErr
+		}
+		states[eachState.Name()] = stateDef
+	}
+	var startAt string
+	if len(branch.States) != 0 {
+		startAt = branch.States[0].Name()
+	}
+	return map[string]interface{}{
+		"StartAt": startAt,
+		"States":  states,
+	}, nil
+}
@@ -0,0 +1,34 @@
+package step
+
+// TaskRetry represents a single Retrier entry in a TaskState's Retry list
+// (https://docs.aws.amazon.com/step-functions/latest/dg/concepts-error-handling.html#error-handling-retrying-after-an-error).
+type TaskRetry struct {
+	// ErrorEquals is the set of error names this retrier matches, eg
+	// "States.TaskFailed" or a custom error name raised by the Lambda.
+	ErrorEquals []string
+	// IntervalSeconds is the number of seconds before the first retry
+	// attempt.
+	IntervalSeconds int
+	// MaxAttempts is the maximum number of retry attempts.
+	MaxAttempts int
+	// BackoffRate is the multiplier applied to IntervalSeconds between
+	// successive retries.
+	BackoffRate float64
+}
+
+// marshalASL produces the ASL Retrier block for this TaskRetry.
+func (tr *TaskRetry) marshalASL() map[string]interface{} {
+	entry := map[string]interface{}{
+		"ErrorEquals": tr.ErrorEquals,
+	}
+	if tr.IntervalSeconds != 0 {
+		entry["IntervalSeconds"] = tr.IntervalSeconds
+	}
+	if tr.MaxAttempts != 0 {
+		entry["MaxAttempts"] = tr.MaxAttempts
+	}
+	if tr.BackoffRate != 0 {
+		entry["BackoffRate"] = tr.BackoffRate
+	}
+	return entry
+}
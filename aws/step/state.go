@@ -0,0 +1,55 @@
+package step
+
+// State is implemented by every Amazon States Language state type supported
+// by this package (TaskState, ParallelState, ChoiceState, WaitState).
+type State interface {
+	// Name returns the state's unique name within the enclosing state
+	// machine.
+	Name() string
+	// MarshalASL produces the JSON representation of the state as it
+	// appears in a States.<Name> block of the state machine's
+	// DefinitionString.
+	MarshalASL() (map[string]interface{}, error)
+	// successors returns the states this state transitions to within the
+	// same States map (Next/Default/Choices targets). It does NOT include
+	// states nested inside a ParallelState's Branches, which live in their
+	// own States scope and are walked separately.
+	successors() []State
+}
+
+// baseState holds the fields common to every linear (single-successor)
+// state type: TaskState, WaitState, ParallelState.
+type baseState struct {
+	name      string
+	comment   string
+	nextState State
+	end       bool
+}
+
+// Name returns the state's unique name within the enclosing state machine.
+func (b *baseState) Name() string {
+	return b.name
+}
+
+// successors implements the linear-transition portion of State.
+func (b *baseState) successors() []State {
+	if b.end || b.nextState == nil {
+		return nil
+	}
+	return []State{b.nextState}
+}
+
+func (b *baseState) marshalTransition(aslType string) map[string]interface{} {
+	entry := map[string]interface{}{
+		"Type": aslType,
+	}
+	if b.comment != "" {
+		entry["Comment"] = b.comment
+	}
+	if b.end {
+		entry["End"] = true
+	} else if b.nextState != nil {
+		entry["Next"] = b.nextState.Name()
+	}
+	return entry
+}
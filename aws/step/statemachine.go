@@ -0,0 +1,244 @@
+package step
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sparta "github.com/mweagle/Sparta"
+	gocf "github.com/mweagle/go-cloudformation"
+)
+
+// StateMachine builds an AWS::StepFunctions::StateMachine resource from a
+// graph of State instances, resolving TaskState Resource references to the
+// sparta.LambdaAWSInfo instances they were constructed with.
+type StateMachine struct {
+	name    string
+	startAt State
+	comment string
+}
+
+// NewStateMachine returns a StateMachine rooted at startAt.
+func NewStateMachine(name string, startAt State) *StateMachine {
+	return &StateMachine{
+		name:    name,
+		startAt: startAt,
+	}
+}
+
+// WithComment sets the top level Comment field of the generated state
+// machine definition and returns the StateMachine to support fluent
+// chaining.
+func (sm *StateMachine) WithComment(comment string) *StateMachine {
+	sm.comment = comment
+	return sm
+}
+
+// states walks the top-level transition graph reachable from startAt
+// (Next/Default/Choices), returning each top-level State exactly once. It
+// does not descend into a ParallelState's Branches: those live in their own
+// nested States scope and are marshaled by marshalBranch, so including them
+// here would duplicate them as orphaned top-level entries.
+func (sm *StateMachine) states() []State {
+	visited := map[string]bool{}
+	var ordered []State
+	var visit func(state State)
+	visit = func(state State) {
+		if state == nil || visited[state.Name()] {
+			return
+		}
+		visited[state.Name()] = true
+		ordered = append(ordered, state)
+		for _, eachSuccessor := range state.successors() {
+			visit(eachSuccessor)
+		}
+	}
+	visit(sm.startAt)
+	return ordered
+}
+
+// lambdaFunctions returns every distinct sparta.LambdaAWSInfo referenced by
+// a TaskState anywhere in the graph, including those nested inside
+// ParallelState branches.
+func (sm *StateMachine) lambdaFunctions() []*sparta.LambdaAWSInfo {
+	seen := map[*sparta.LambdaAWSInfo]bool{}
+	var fns []*sparta.LambdaAWSInfo
+	var collect func(states []State)
+	collect = func(states []State) {
+		for _, eachState := range states {
+			switch typedState := eachState.(type) {
+			case *TaskState:
+				if lambdaFn := typedState.lambdaFunction(); !seen[lambdaFn] {
+					seen[lambdaFn] = true
+					fns = append(fns, lambdaFn)
+				}
+			case *ParallelState:
+				for _, eachBranch := range typedState.branches {
+					collect(eachBranch.States)
+				}
+			}
+		}
+	}
+	collect(sm.states())
+	return fns
+}
+
+// lambdaLogicalResourceNames builds the map from each referenced
+// sparta.LambdaAWSInfo to the logical CloudFormation resource name its
+// AWS::Lambda::Function resource is provisioned under, matching the naming
+// LambdaAWSInfo.export uses internally. This must resolve against
+// eachFn.Name() (the name the function was registered under via
+// HandleAWSLambda) rather than sparta.LambdaName(eachFn.Handler): export
+// names the resource from Name(), and a Lambda registered under a custom
+// name would otherwise produce a logical id that doesn't exist in the
+// template.
+func lambdaLogicalResourceNames(lambdaFns []*sparta.LambdaAWSInfo) map[*sparta.LambdaAWSInfo]string {
+	names := make(map[*sparta.LambdaAWSInfo]string, len(lambdaFns))
+	for _, eachFn := range lambdaFns {
+		names[eachFn] = sparta.CloudFormationResourceName("Lambda", eachFn.Name())
+	}
+	return names
+}
+
+// resolveLambdaResources walks a marshaled ASL node tree and replaces every
+// *sparta.LambdaAWSInfo value TaskState.MarshalASL left behind with an
+// Fn::GetAtt reference to that Lambda's logical resource name.
+func resolveLambdaResources(node interface{}, logicalNames map[*sparta.LambdaAWSInfo]string) (interface{}, error) {
+	switch typedNode := node.(type) {
+	case *sparta.LambdaAWSInfo:
+		logicalName, exists := logicalNames[typedNode]
+		if !exists {
+			return nil, fmt.Errorf("state machine references an unresolved Lambda function: %s",
+				sparta.LambdaName(typedNode.Handler))
+		}
+		return gocf.GetAtt(logicalName, "Arn"), nil
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(typedNode))
+		for key, value := range typedNode {
+			resolvedValue, err := resolveLambdaResources(value, logicalNames)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = resolvedValue
+		}
+		return resolved, nil
+	case []map[string]interface{}:
+		resolved := make([]map[string]interface{}, len(typedNode))
+		for index, value := range typedNode {
+			resolvedValue, err := resolveLambdaResources(value, logicalNames)
+			if err != nil {
+				return nil, err
+			}
+			resolved[index] = resolvedValue.(map[string]interface{})
+		}
+		return resolved, nil
+	default:
+		return node, nil
+	}
+}
+
+// definitionString marshals the full Amazon States Language document for
+// this state machine, replacing each TaskState's Lambda reference with an
+// Fn::GetAtt against logicalNames.
+func (sm *StateMachine) definitionString(logicalNames map[*sparta.LambdaAWSInfo]string) (string, error) {
+	states := map[string]interface{}{}
+	for _, eachState := range sm.states() {
+		stateDef, stateErr := eachState.MarshalASL()
+		if stateErr != nil {
+			return "", stateErr
+		}
+		resolved, resolveErr := resolveLambdaResources(stateDef, logicalNames)
+		if resolveErr != nil {
+			return "", resolveErr
+		}
+		states[eachState.Name()] = resolved
+	}
+	definition := map[string]interface{}{
+		"StartAt": sm.startAt.Name(),
+		"States":  states,
+	}
+	if sm.comment != "" {
+		definition["Comment"] = sm.comment
+	}
+	if validateErr := validateASL(definition); validateErr != nil {
+		return "", fmt.Errorf("state machine %s failed ASL validation: %s", sm.name, validateErr)
+	}
+	definitionJSON, marshalErr := json.Marshal(definition)
+	if marshalErr != nil {
+		return "", marshalErr
+	}
+	return string(definitionJSON), nil
+}
+
+// executionRole emits the AWS::IAM::Role this state machine runs as,
+// trusting the states.amazonaws.com service principal and granting
+// lambda:InvokeFunction against every Lambda the graph's Task states
+// reference, plus states:StartExecution so the machine may itself start
+// other Step Functions executions.
+func (sm *StateMachine) executionRole(serviceName string, lambdaFns []*sparta.LambdaAWSInfo, logicalNames map[*sparta.LambdaAWSInfo]string) (string, *gocf.IAMRole) {
+	resourceARNs := make([]interface{}, 0, len(lambdaFns))
+	for _, eachFn := range lambdaFns {
+		resourceARNs = append(resourceARNs, gocf.GetAtt(logicalNames[eachFn], "Arn"))
+	}
+	role := &gocf.IAMRole{
+		AssumeRolePolicyDocument: map[string]interface{}{
+			"Version": "2012-10-17",
+			"Statement": []map[string]interface{}{
+				{
+					"Effect":    "Allow",
+					"Principal": map[string]interface{}{"Service": "states.amazonaws.com"},
+					"Action":    "sts:AssumeRole",
+				},
+			},
+		},
+		Policies: []gocf.IAMPolicies{
+			{
+				PolicyName: gocf.String(fmt.Sprintf("%s-StateMachinePolicy", sm.name)),
+				PolicyDocument: map[string]interface{}{
+					"Version": "2012-10-17",
+					"Statement": []map[string]interface{}{
+						{
+							"Effect":   "Allow",
+							"Action":   "lambda:InvokeFunction",
+							"Resource": resourceARNs,
+						},
+						{
+							"Effect":   "Allow",
+							"Action":   "states:StartExecution",
+							"Resource": "*",
+						},
+					},
+				},
+			},
+		},
+	}
+	return sparta.CloudFormationResourceName("IAMRole", serviceName, sm.name), role
+}
+
+// CloudFormationResource emits the AWS::StepFunctions::StateMachine
+// resource for this StateMachine into template, along with the IAM
+// execution role statements (states:StartExecution, lambda:InvokeFunction)
+// it requires, and returns the same state machine resource already added to
+// template. The IAM role is added to template as its own resource because
+// RoleArn references it via Fn::GetAtt.
+func (sm *StateMachine) CloudFormationResource(serviceName string, template *gocf.Template) (*gocf.StepFunctionsStateMachine, error) {
+	lambdaFns := sm.lambdaFunctions()
+	logicalNames := lambdaLogicalResourceNames(lambdaFns)
+
+	definition, definitionErr := sm.definitionString(logicalNames)
+	if definitionErr != nil {
+		return nil, fmt.Errorf("failed to marshal state machine %s: %s", sm.name, definitionErr)
+	}
+
+	roleResourceName, role := sm.executionRole(serviceName, lambdaFns, logicalNames)
+	template.AddResource(roleResourceName, role)
+
+	stateMachineResourceName := sparta.CloudFormationResourceName("StateMachine", serviceName, sm.name)
+	stateMachineResource := &gocf.StepFunctionsStateMachine{
+		StateMachineName: gocf.String(sm.name),
+		DefinitionString: gocf.String(definition),
+		RoleArn:          gocf.GetAtt(roleResourceName, "Arn"),
+	}
+	template.AddResource(stateMachineResourceName, stateMachineResource)
+
+	return stateMachineResource, nil
+}
@@ -0,0 +1,65 @@
+package step
+
+import (
+	sparta "github.com/mweagle/Sparta"
+)
+
+// TaskState is an ASL Task state whose Resource resolves to the ARN of a
+// sparta.LambdaAWSInfo instance defined elsewhere in the service.
+type TaskState struct {
+	baseState
+	lambdaFn *sparta.LambdaAWSInfo
+	retriers []*TaskRetry
+}
+
+// NewTaskState returns a TaskState that invokes the given Lambda function.
+func NewTaskState(name string, lambdaFn *sparta.LambdaAWSInfo) *TaskState {
+	return &TaskState{
+		baseState: baseState{name: name},
+		lambdaFn:  lambdaFn,
+	}
+}
+
+// WithRetriers appends one or more TaskRetry entries to the state's Retry
+// list and returns the TaskState to support fluent chaining.
+func (ts *TaskState) WithRetriers(retriers ...*TaskRetry) *TaskState {
+	ts.retriers = append(ts.retriers, retriers...)
+	return ts
+}
+
+// WithNext sets the state to transition to on successful completion and
+// returns the TaskState to support fluent chaining.
+func (ts *TaskState) WithNext(next State) *TaskState {
+	ts.baseState.nextState = next
+	return ts
+}
+
+// WithEnd marks this TaskState as a terminal state of the state machine.
+func (ts *TaskState) WithEnd() *TaskState {
+	ts.baseState.end = true
+	return ts
+}
+
+// MarshalASL implements State. The Resource value is the *sparta.LambdaAWSInfo
+// this task invokes rather than a string; the owning StateMachine walks the
+// marshaled definition and replaces each one with an Fn::GetAtt reference
+// once it knows the Lambda's logical CloudFormation resource name, since the
+// physical ARN isn't known until the template is resolved.
+func (ts *TaskState) MarshalASL() (map[string]interface{}, error) {
+	entry := ts.baseState.marshalTransition("Task")
+	entry["Resource"] = ts.lambdaFn
+	if len(ts.retriers) != 0 {
+		retry := make([]map[string]interface{}, len(ts.retriers))
+		for index, eachRetrier := range ts.retriers {
+			retry[index] = eachRetrier.marshalASL()
+		}
+		entry["Retry"] = retry
+	}
+	return entry, nil
+}
+
+// lambdaFunction returns the Lambda function this task state invokes, used
+// by StateMachine to resolve Fn::GetAtt references and IAM statements.
+func (ts *TaskState) lambdaFunction() *sparta.LambdaAWSInfo {
+	return ts.lambdaFn
+}
@@ -0,0 +1,119 @@
+package step
+
+import "fmt"
+
+// validASLStateTypes enumerates the ASL state Type values this package's
+// State implementations ever produce.
+var validASLStateTypes = map[string]bool{
+	"Task":     true,
+	"Parallel": true,
+	"Choice":   true,
+	"Wait":     true,
+}
+
+// validateASL structurally checks a marshaled state machine definition
+// (StartAt/States, and recursively each Branch's own StartAt/States) against
+// the subset of the Amazon States Language this package emits. There's no
+// JSON-schema library vendored into this tree, so this hand-rolled pass
+// stands in for one: it catches the mistakes a schema would (a dangling
+// StartAt/Next/Default, a Task with no Resource, a Choice with no Choices,
+// a Parallel with no Branches) before the definition is uploaded.
+func validateASL(definition map[string]interface{}) error {
+	states, statesErr := requireStates(definition)
+	if statesErr != nil {
+		return statesErr
+	}
+	return validateStartAtAndStates(definition, states)
+}
+
+// validateStartAtAndStates checks that definition's StartAt names a key
+// present in states, then validates every entry in states.
+func validateStartAtAndStates(definition map[string]interface{}, states map[string]interface{}) error {
+	startAt, ok := definition["StartAt"].(string)
+	if !ok || startAt == "" {
+		return fmt.Errorf("missing or empty StartAt")
+	}
+	if _, exists := states[startAt]; !exists {
+		return fmt.Errorf("StartAt %q does not name a state in States", startAt)
+	}
+	for name, eachState := range states {
+		stateDef, ok := eachState.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("state %q is not a JSON object", name)
+		}
+		if validateErr := validateState(name, stateDef, states); validateErr != nil {
+			return validateErr
+		}
+	}
+	return nil
+}
+
+// requireStates type-asserts definition["States"] into a non-empty map.
+func requireStates(definition map[string]interface{}) (map[string]interface{}, error) {
+	states, ok := definition["States"].(map[string]interface{})
+	if !ok || len(states) == 0 {
+		return nil, fmt.Errorf("missing or empty States")
+	}
+	return states, nil
+}
+
+// validateState checks a single state's Type-specific required fields and,
+// for non-terminal states, that Next names a sibling in states.
+func validateState(name string, stateDef map[string]interface{}, states map[string]interface{}) error {
+	stateType, ok := stateDef["Type"].(string)
+	if !ok || !validASLStateTypes[stateType] {
+		return fmt.Errorf("state %q has missing or unsupported Type %v", name, stateDef["Type"])
+	}
+
+	switch stateType {
+	case "Task":
+		if stateDef["Resource"] == nil {
+			return fmt.Errorf("Task state %q is missing Resource", name)
+		}
+	case "Choice":
+		choices, ok := stateDef["Choices"].([]map[string]interface{})
+		if !ok || len(choices) == 0 {
+			return fmt.Errorf("Choice state %q has no Choices", name)
+		}
+		for index, eachChoice := range choices {
+			next, ok := eachChoice["Next"].(string)
+			if !ok || next == "" {
+				return fmt.Errorf("Choice state %q Choices[%d] is missing Next", name, index)
+			}
+			if _, exists := states[next]; !exists {
+				return fmt.Errorf("Choice state %q Choices[%d] Next %q does not name a state in States", name, index, next)
+			}
+		}
+		if deflt, hasDefault := stateDef["Default"].(string); hasDefault {
+			if _, exists := states[deflt]; !exists {
+				return fmt.Errorf("Choice state %q Default %q does not name a state in States", name, deflt)
+			}
+		}
+		return nil
+	case "Parallel":
+		branches, ok := stateDef["Branches"].([]map[string]interface{})
+		if !ok || len(branches) == 0 {
+			return fmt.Errorf("Parallel state %q has no Branches", name)
+		}
+		for index, eachBranch := range branches {
+			branchStates, branchStatesErr := requireStates(eachBranch)
+			if branchStatesErr != nil {
+				return fmt.Errorf("Parallel state %q Branches[%d]: %s", name, index, branchStatesErr)
+			}
+			if branchErr := validateStartAtAndStates(eachBranch, branchStates); branchErr != nil {
+				return fmt.Errorf("Parallel state %q Branches[%d]: %s", name, index, branchErr)
+			}
+		}
+	}
+
+	if end, _ := stateDef["End"].(bool); end {
+		return nil
+	}
+	if next, hasNext := stateDef["Next"].(string); hasNext {
+		if _, exists := states[next]; !exists {
+			return fmt.Errorf("state %q Next %q does not name a state in States", name, next)
+		}
+		return nil
+	}
+	return fmt.Errorf("state %q is neither terminal (End) nor has a Next", name)
+}
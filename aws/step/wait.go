@@ -0,0 +1,44 @@
+package step
+
+// WaitState is an ASL Wait state that delays the state machine for a fixed
+// number of seconds, until an absolute timestamp, or until a value in the
+// input referenced by SecondsPath/TimestampPath.
+type WaitState struct {
+	baseState
+	Seconds       int
+	Timestamp     string
+	SecondsPath   string
+	TimestampPath string
+}
+
+// NewWaitState returns a WaitState that pauses for the given number of
+// seconds.
+func NewWaitState(name string, seconds int) *WaitState {
+	return &WaitState{
+		baseState: baseState{name: name},
+		Seconds:   seconds,
+	}
+}
+
+// WithNext sets the state to transition to after the wait elapses and
+// returns the WaitState to support fluent chaining.
+func (ws *WaitState) WithNext(next State) *WaitState {
+	ws.baseState.nextState = next
+	return ws
+}
+
+// MarshalASL implements State.
+func (ws *WaitState) MarshalASL() (map[string]interface{}, error) {
+	entry := ws.baseState.marshalTransition("Wait")
+	switch {
+	case ws.SecondsPath != "":
+		entry["SecondsPath"] = ws.SecondsPath
+	case ws.TimestampPath != "":
+		entry["TimestampPath"] = ws.TimestampPath
+	case ws.Timestamp != "":
+		entry["Timestamp"] = ws.Timestamp
+	default:
+		entry["Seconds"] = ws.Seconds
+	}
+	return entry, nil
+}
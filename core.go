@@ -0,0 +1,255 @@
+package sparta
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+
+	gocf "github.com/mweagle/go-cloudformation"
+	"github.com/sirupsen/logrus"
+)
+
+// nonAlphanumeric matches every rune that isn't a CloudFormation logical ID
+// character, for stripping out of generated resource names.
+var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// BasePermission is embedded by every permission type (S3Permission,
+// SNSPermission, CodeCommitPermission, ...) and carries the fields common to
+// an AWS::Lambda::Permission grant.
+type BasePermission struct {
+	// SourceArn is the ARN of the AWS resource permitted to invoke the
+	// function.
+	SourceArn string
+	// SourceAccount optionally restricts the grant to a specific AWS
+	// account ID.
+	SourceAccount string
+}
+
+// export emits the AWS::Lambda::Permission resource granting principal the
+// ability to invoke the Lambda identified by lambdaLogicalCFResourceName,
+// scoped to SourceArn, and returns the new resource's logical name.
+func (perm *BasePermission) export(principal string,
+	serviceName string,
+	lambdaLogicalCFResourceName string,
+	template *gocf.Template,
+	logger *logrus.Logger) (string, error) {
+
+	permissionResourceName := CloudFormationResourceName("LambdaPerm", lambdaLogicalCFResourceName, principal)
+	lambdaPermission := &gocf.LambdaPermission{
+		Action:       gocf.String("lambda:InvokeFunction"),
+		FunctionName: gocf.GetAtt(lambdaLogicalCFResourceName, "Arn"),
+		Principal:    gocf.String(principal),
+	}
+	if perm.SourceArn != "" {
+		lambdaPermission.SourceArn = gocf.String(perm.SourceArn)
+	}
+	if perm.SourceAccount != "" {
+		lambdaPermission.SourceAccount = gocf.String(perm.SourceAccount)
+	}
+	template.AddResource(permissionResourceName, lambdaPermission)
+	return permissionResourceName, nil
+}
+
+// S3Permission grants an S3 bucket the ability to invoke a Lambda function
+// in response to the given Events
+// (http://docs.aws.amazon.com/AmazonS3/latest/dev/NotificationHowTo.html).
+type S3Permission struct {
+	BasePermission
+	Events []string
+}
+
+// export grants the S3 service principal lambda:InvokeFunction for this
+// Lambda.
+func (perm S3Permission) export(serviceName string,
+	lambdaLogicalCFResourceName string,
+	template *gocf.Template,
+	S3Bucket string,
+	S3Key string,
+	logger *logrus.Logger) (string, error) {
+	return perm.BasePermission.export("s3.amazonaws.com",
+		serviceName,
+		lambdaLogicalCFResourceName,
+		template,
+		logger)
+}
+
+// SNSPermission grants an SNS topic the ability to invoke a Lambda
+// function.
+type SNSPermission struct {
+	BasePermission
+}
+
+// export grants the SNS service principal lambda:InvokeFunction for this
+// Lambda.
+func (perm SNSPermission) export(serviceName string,
+	lambdaLogicalCFResourceName string,
+	template *gocf.Template,
+	S3Bucket string,
+	S3Key string,
+	logger *logrus.Logger) (string, error) {
+	return perm.BasePermission.export("sns.amazonaws.com",
+		serviceName,
+		lambdaLogicalCFResourceName,
+		template,
+		logger)
+}
+
+// lambdaPermission is implemented by every permission type and mirrors the
+// export signature used across S3Permission/SNSPermission/CodeCommitPermission.
+type lambdaPermission interface {
+	export(serviceName string,
+		lambdaLogicalCFResourceName string,
+		template *gocf.Template,
+		S3Bucket string,
+		S3Key string,
+		logger *logrus.Logger) (string, error)
+}
+
+// LambdaAWSInfo collects the information needed to provision a single AWS
+// Lambda function as part of a Sparta service: the Go handler, its IAM
+// role, and the event sources/permissions/decorators that attach to it.
+type LambdaAWSInfo struct {
+	// lambdaFnName is the name this Lambda was registered under via
+	// HandleAWSLambda, used to route local/local-execution requests and to name the
+	// Lambda's CloudFormation resource.
+	lambdaFnName string
+	// Handler is the Go function provisioned as the Lambda entry point.
+	Handler interface{}
+	// RoleName or RoleArn identifies the IAM role the function executes
+	// as. Exactly one of RoleName/RoleArn is expected to be set.
+	RoleName string
+	RoleArn  string
+	// Permissions are the event sources (S3, SNS, CodeCommit, ...) that
+	// are granted lambda:InvokeFunction against this function.
+	Permissions []lambdaPermission
+	// EventSourceMappings are the poll-based event sources (DynamoDB
+	// Streams, Kinesis, SQS) that invoke this function.
+	EventSourceMappings []*EventSourceMapping
+	// DependsOn names additional CloudFormation resources (by logical
+	// name) this Lambda depends on. Populated via Decorator-created
+	// resources or user code; consumed by Discover() at runtime.
+	DependsOn []string
+	// Decorator, when non-nil, is invoked after this Lambda's base
+	// CloudFormation resource is materialized but before the template is
+	// validated, so it can add sibling resources the Lambda depends on.
+	Decorator Decorator
+}
+
+// lambdaFunctionName returns the name this Lambda was registered under via
+// HandleAWSLambda (the same value as Name()).
+func (info *LambdaAWSInfo) lambdaFunctionName() string {
+	return info.Name()
+}
+
+// Name returns the logical name this Lambda was registered under via
+// HandleAWSLambda. It's the name export uses to derive the Lambda's
+// CloudFormation logical resource id, so anything resolving that id
+// (eg aws/step's TaskState) must use Name() rather than re-deriving it from
+// Handler.
+func (info *LambdaAWSInfo) Name() string {
+	return info.lambdaFnName
+}
+
+// HandleAWSLambda creates a LambdaAWSInfo that provisions lambdaFn as an AWS
+// Lambda function executing as roleNameOrArn.
+func HandleAWSLambda(name string, lambdaFn interface{}, roleNameOrArn string) *LambdaAWSInfo {
+	info := &LambdaAWSInfo{
+		lambdaFnName: name,
+		Handler:      lambdaFn,
+	}
+	if strings.HasPrefix(roleNameOrArn, "arn:") {
+		info.RoleArn = roleNameOrArn
+	} else {
+		info.RoleName = roleNameOrArn
+	}
+	return info
+}
+
+// LambdaName returns the Go runtime name of a handler function, suitable
+// for use as a Lambda's logical name.
+func LambdaName(lambdaFn interface{}) string {
+	fullName := runtime.FuncForPC(reflect.ValueOf(lambdaFn).Pointer()).Name()
+	parts := strings.Split(fullName, ".")
+	return parts[len(parts)-1]
+}
+
+// CloudFormationResourceName derives a stable, alphanumeric CloudFormation
+// logical resource name from the given prefix and parts.
+func CloudFormationResourceName(prefix string, parts ...string) string {
+	name := prefix
+	for _, eachPart := range parts {
+		name += nonAlphanumeric.ReplaceAllString(eachPart, "")
+	}
+	return name
+}
+
+// export materializes this Lambda's AWS::Lambda::Function resource, then
+// runs the provisioning steps that depend on it: attaching
+// Permissions/EventSourceMappings and invoking Decorator, all before the
+// template is handed off for validation.
+func (info *LambdaAWSInfo) export(serviceName string,
+	S3Bucket string,
+	S3Key string,
+	buildID string,
+	template *gocf.Template,
+	ctx map[string]interface{},
+	logger *logrus.Logger) (string, error) {
+
+	lambdaResourceName := CloudFormationResourceName("Lambda", info.lambdaFunctionName())
+	lambdaResource := gocf.LambdaFunction{
+		Handler: gocf.String(info.lambdaFunctionName()),
+		Code: &gocf.LambdaFunctionCode{
+			S3Bucket: gocf.String(S3Bucket),
+			S3Key:    gocf.String(S3Key),
+		},
+	}
+	if info.RoleArn != "" {
+		lambdaResource.Role = gocf.String(info.RoleArn)
+	} else if info.RoleName != "" {
+		lambdaResource.Role = gocf.GetAtt(info.RoleName, "Arn")
+	}
+	template.AddResource(lambdaResourceName, lambdaResource)
+
+	for _, eachPermission := range info.Permissions {
+		if _, permErr := eachPermission.export(serviceName,
+			lambdaResourceName,
+			template,
+			S3Bucket,
+			S3Key,
+			logger); permErr != nil {
+			return "", permErr
+		}
+	}
+	for _, eachMapping := range info.EventSourceMappings {
+		if _, mappingErr := eachMapping.export(lambdaResourceName, template); mappingErr != nil {
+			return "", mappingErr
+		}
+	}
+
+	if info.Decorator != nil {
+		resourceMetadata := map[string]interface{}{}
+		decoratorErr := info.Decorator(serviceName,
+			lambdaResourceName,
+			lambdaResource,
+			resourceMetadata,
+			S3Bucket,
+			S3Key,
+			buildID,
+			template,
+			ctx,
+			logger)
+		if decoratorErr != nil {
+			return "", fmt.Errorf("decorator for %s failed: %s", lambdaResourceName, decoratorErr)
+		}
+	}
+
+	if len(info.DependsOn) != 0 {
+		if discoveryErr := injectDiscoveryInfo(info, &lambdaResource, template); discoveryErr != nil {
+			return "", discoveryErr
+		}
+		template.AddResource(lambdaResourceName, lambdaResource)
+	}
+	return lambdaResourceName, nil
+}
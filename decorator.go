@@ -0,0 +1,26 @@
+package sparta
+
+import (
+	gocf "github.com/mweagle/go-cloudformation"
+	"github.com/sirupsen/logrus"
+)
+
+// Decorator is a hook invoked during provisioning that allows a
+// LambdaAWSInfo to add sibling CloudFormation resources (SNS topics, SQS
+// queues, Kinesis streams, KMS keys, ...) to the service template. Assign it
+// to the LambdaAWSInfo.Decorator field; LambdaAWSInfo.export calls it after
+// the owning Lambda's resource has been materialized but before the
+// template is validated, so decorator-created resources participate in
+// dependency ordering. The Lambda's Permissions are exported before the
+// decorator runs, and BasePermission.SourceArn is a literal string, so a
+// decorator-created resource cannot be referenced there via gocf.Ref(...).
+type Decorator func(serviceName string,
+	lambdaResourceName string,
+	lambdaResource gocf.LambdaFunction,
+	resourceMetadata map[string]interface{},
+	S3Bucket string,
+	S3Key string,
+	buildID string,
+	template *gocf.Template,
+	ctx map[string]interface{},
+	logger *logrus.Logger) error
@@ -0,0 +1,128 @@
+package sparta
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	gocf "github.com/mweagle/go-cloudformation"
+)
+
+// envVarDiscoveryInformation is the name of the environment variable
+// populated at provision time with the JSON-encoded discovery metadata for
+// a Lambda's DependsOn resources.
+const envVarDiscoveryInformation = "SPARTA_DISCOVERY_INFO"
+
+// DiscoveryResourceInfo describes a single resource discovered via
+// Discover(): its CloudFormation type, the Ref value AWS assigned it at
+// stack creation (eg the physical bucket name or queue URL). Properties is
+// not populated by Sparta: the document injected at provision time carries
+// only Ref and Type for each resource.
+type DiscoveryResourceInfo struct {
+	Ref        string
+	Type       string
+	Properties map[string]interface{}
+}
+
+// DiscoveryInfo is the result of a successful Discover() call: the
+// CloudFormation logical name of every resource the running Lambda
+// DependsOn, mapped to its resolved physical information.
+type DiscoveryInfo struct {
+	Resources map[string]DiscoveryResourceInfo
+}
+
+var (
+	discoveryInfoOnce  sync.Once
+	discoveryInfoCache *DiscoveryInfo
+	discoveryInfoErr   error
+)
+
+// Discover returns the CloudFormation logical-name to physical-resource map
+// for every resource the currently executing Lambda function DependsOn.
+// Sparta injects this information into the function's
+// SPARTA_DISCOVERY_INFO environment variable at provision time (no
+// Metadata block is written); Discover reads it back out of that variable
+// so handlers can locate sibling resources (an S3 bucket, an SNS topic,
+// ...) that were created in the same stack without hardcoding their names.
+// The result of the first call, error included, is cached since the
+// information is immutable for the lifetime of the execution environment.
+func Discover() (*DiscoveryInfo, error) {
+	discoveryInfoOnce.Do(func() {
+		discoveryInfoCache, discoveryInfoErr = discoverFromEnvironment()
+	})
+	return discoveryInfoCache, discoveryInfoErr
+}
+
+// discoveryInfoExpr builds the Fn::Join expression that assembles the
+// SPARTA_DISCOVERY_INFO JSON document for dependsOn, resolved against the
+// resources already present in template. Resources Decorator created are
+// included as long as they were added to the template (and to
+// info.DependsOn) before this runs.
+//
+// The "Ref" value for each dependency can't be known until CloudFormation
+// creates the stack - a bucket's physical name, a queue's URL, etc - so it
+// can't simply be json.Marshal'd into a literal string at build time (that
+// would bake the *logical* name in instead). Each dependency's Ref is
+// therefore left as a gocf.Ref(logicalName) intrinsic; Fn::Join stitches
+// those together with the surrounding literal JSON text, and CloudFormation
+// substitutes the physical IDs when it resolves the Lambda's environment
+// variables at stack-creation time.
+func discoveryInfoExpr(dependsOn []string, template *gocf.Template) (*gocf.StringExpr, error) {
+	if len(dependsOn) == 0 {
+		return nil, nil
+	}
+	joinParts := []interface{}{`{"Resources":{`}
+	for index, eachDependencyName := range dependsOn {
+		dependency, exists := template.Resources[eachDependencyName]
+		if !exists {
+			return nil, fmt.Errorf("Discover() dependency %s is not present in the template", eachDependencyName)
+		}
+		nameJSON, _ := json.Marshal(eachDependencyName)
+		typeJSON, _ := json.Marshal(dependency.CfnResourceType())
+		if index != 0 {
+			joinParts = append(joinParts, ",")
+		}
+		joinParts = append(joinParts,
+			fmt.Sprintf(`%s:{"Ref":"`, nameJSON),
+			gocf.Ref(eachDependencyName),
+			fmt.Sprintf(`","Type":%s}`, typeJSON))
+	}
+	joinParts = append(joinParts, "}}")
+	return gocf.Join("", joinParts...), nil
+}
+
+// injectDiscoveryInfo sets envVarDiscoveryInformation on lambdaResource's
+// Environment to the Fn::Join expression produced by discoveryInfoExpr for
+// info.DependsOn, so a Discover() call at runtime can read back the
+// logical-name-to-physical-ID map CloudFormation resolved into it. It's a
+// no-op when DependsOn is empty.
+func injectDiscoveryInfo(info *LambdaAWSInfo, lambdaResource *gocf.LambdaFunction, template *gocf.Template) error {
+	expr, exprErr := discoveryInfoExpr(info.DependsOn, template)
+	if exprErr != nil {
+		return exprErr
+	}
+	if expr == nil {
+		return nil
+	}
+	if lambdaResource.Environment == nil {
+		lambdaResource.Environment = &gocf.LambdaFunctionEnvironment{
+			Variables: map[string]*gocf.StringExpr{},
+		}
+	}
+	lambdaResource.Environment.Variables[envVarDiscoveryInformation] = expr
+	return nil
+}
+
+func discoverFromEnvironment() (*DiscoveryInfo, error) {
+	rawInfo := os.Getenv(envVarDiscoveryInformation)
+	if rawInfo == "" {
+		return nil, fmt.Errorf("Discover() called but %s is not set - is this running inside a Sparta-provisioned Lambda?",
+			envVarDiscoveryInformation)
+	}
+	var info DiscoveryInfo
+	if unmarshalErr := json.Unmarshal([]byte(rawInfo), &info); unmarshalErr != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %s", envVarDiscoveryInformation, unmarshalErr)
+	}
+	return &info, nil
+}
@@ -0,0 +1,109 @@
+package sparta
+
+import (
+	"encoding/json"
+	"fmt"
+
+	gocf "github.com/mweagle/go-cloudformation"
+)
+
+const (
+	// maxFilterCriteriaPatternBytes is the maximum length, in bytes, of a single
+	// FilterCriteria pattern as enforced by the Lambda event source filtering API.
+	// See https://docs.aws.amazon.com/lambda/latest/dg/invocation-eventfiltering.html
+	maxFilterCriteriaPatternBytes = 4096
+	// maxFilterCriteriaFilters is the maximum number of Filters permitted in a
+	// single FilterCriteria block.
+	maxFilterCriteriaFilters = 5
+)
+
+// EventFilter represents a single entry in an EventSourceMapping's
+// FilterCriteria.Filters list. Pattern is a JSON filter pattern as described at
+// https://docs.aws.amazon.com/lambda/latest/dg/invocation-eventfiltering.html
+type EventFilter struct {
+	Pattern string
+}
+
+// EventSourceMapping represents the golang representation of an AWS Lambda
+// EventSourceMapping
+// (http://docs.aws.amazon.com/lambda/latest/dg/API_CreateEventSourceMapping.html)
+type EventSourceMapping struct {
+	EventSourceArn   string
+	StartingPosition string
+	Disabled         bool
+	BatchSize        int
+	// FilterCriteria restricts which records from EventSourceArn invoke the
+	// function, so that invocations that would otherwise immediately return
+	// are never dispatched. See
+	// https://docs.aws.amazon.com/lambda/latest/dg/invocation-eventfiltering.html
+	FilterCriteria []EventFilter
+}
+
+// validate ensures the EventSourceMapping's FilterCriteria (if any) is well
+// formed before the owning CloudFormation resource is marshaled.
+func (esm *EventSourceMapping) validate() error {
+	if len(esm.FilterCriteria) > maxFilterCriteriaFilters {
+		return fmt.Errorf("EventSourceMapping FilterCriteria supports at most %d filters, got %d",
+			maxFilterCriteriaFilters,
+			len(esm.FilterCriteria))
+	}
+	for _, eachFilter := range esm.FilterCriteria {
+		if len(eachFilter.Pattern) > maxFilterCriteriaPatternBytes {
+			return fmt.Errorf("EventSourceMapping FilterCriteria pattern exceeds %d byte limit: %s",
+				maxFilterCriteriaPatternBytes,
+				eachFilter.Pattern)
+		}
+		var discard interface{}
+		if err := json.Unmarshal([]byte(eachFilter.Pattern), &discard); err != nil {
+			return fmt.Errorf("EventSourceMapping FilterCriteria pattern is not valid JSON: %s (%s)",
+				eachFilter.Pattern,
+				err)
+		}
+	}
+	return nil
+}
+
+// toCloudFormationResource marshals the EventSourceMapping into the
+// CloudFormation resource consumed by the provisioning pipeline, translating
+// FilterCriteria into the `AWS::Lambda::EventSourceMapping.FilterCriteria`
+// shape.
+func (esm *EventSourceMapping) toCloudFormationResource() (*gocf.LambdaEventSourceMapping, error) {
+	if err := esm.validate(); err != nil {
+		return nil, err
+	}
+	cfResource := &gocf.LambdaEventSourceMapping{
+		EventSourceArn:   gocf.String(esm.EventSourceArn),
+		StartingPosition: gocf.String(esm.StartingPosition),
+		Enabled:          gocf.Bool(!esm.Disabled),
+		BatchSize:        gocf.Integer(int64(esm.BatchSize)),
+	}
+	if len(esm.FilterCriteria) != 0 {
+		filters := make([]*gocf.LambdaEventSourceMappingFilter, len(esm.FilterCriteria))
+		for index, eachFilter := range esm.FilterCriteria {
+			filters[index] = &gocf.LambdaEventSourceMappingFilter{
+				Pattern: gocf.String(eachFilter.Pattern),
+			}
+		}
+		cfResource.FilterCriteria = &gocf.LambdaEventSourceMappingFilterCriteria{
+			Filters: filters,
+		}
+	}
+	return cfResource, nil
+}
+
+// export validates this EventSourceMapping and adds its
+// AWS::Lambda::EventSourceMapping resource to template, returning the new
+// resource's logical name. This is the provisioning-path entry point that
+// rejects unparseable/oversized/too-numerous FilterCriteria patterns before
+// they ever reach CloudFormation.
+func (esm *EventSourceMapping) export(lambdaLogicalCFResourceName string,
+	template *gocf.Template) (string, error) {
+	cfResource, err := esm.toCloudFormationResource()
+	if err != nil {
+		return "", err
+	}
+	cfResource.FunctionName = gocf.GetAtt(lambdaLogicalCFResourceName, "Arn")
+	resourceName := CloudFormationResourceName("EventSourceMapping", lambdaLogicalCFResourceName, esm.EventSourceArn)
+	template.AddResource(resourceName, cfResource)
+	return resourceName, nil
+}
@@ -0,0 +1,234 @@
+package sparta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"syscall"
+
+	awsLambdaEvents "github.com/aws/aws-lambda-go/events"
+	"github.com/sirupsen/logrus"
+)
+
+var errorInterfaceType = reflect.TypeOf((*error)(nil)).Elem()
+var contextInterfaceType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// invokeLocal calls info.Handler via reflection, the same way the real
+// Lambda runtime would dispatch to a handler registered with
+// lambda.Start(fn): the handler takes at most two arguments, and when it
+// takes two the first must accept context.Context; a first argument that
+// accepts context.Context receives ctx, and the last argument otherwise
+// receives input decoded (via a JSON round-trip so unmarshalTarget's
+// concrete type need not match the handler's event type exactly) into that
+// argument's type; it returns either (error) or (T, error).
+func (info *LambdaAWSInfo) invokeLocal(ctx context.Context, input interface{}) (interface{}, error) {
+	handlerValue := reflect.ValueOf(info.Handler)
+	handlerType := handlerValue.Type()
+	if handlerType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("%s Handler is not a function", info.Name())
+	}
+
+	args := make([]reflect.Value, 0, handlerType.NumIn())
+	for argIndex := 0; argIndex < handlerType.NumIn(); argIndex++ {
+		argType := handlerType.In(argIndex)
+		switch {
+		case argIndex == 0 && argType.Implements(contextInterfaceType):
+			args = append(args, reflect.ValueOf(ctx))
+		case argIndex <= 1 && argIndex == handlerType.NumIn()-1:
+			argValue := reflect.New(argType)
+			raw, marshalErr := json.Marshal(input)
+			if marshalErr != nil {
+				return nil, fmt.Errorf("failed to marshal input for %s: %s", info.Name(), marshalErr)
+			}
+			if unmarshalErr := json.Unmarshal(raw, argValue.Interface()); unmarshalErr != nil {
+				return nil, fmt.Errorf("failed to decode input into %s's handler argument: %s", info.Name(), unmarshalErr)
+			}
+			args = append(args, argValue.Elem())
+		default:
+			return nil, fmt.Errorf("%s Handler has an unsupported signature: %s", info.Name(), handlerType)
+		}
+	}
+
+	results := handlerValue.Call(args)
+	if len(results) == 0 || len(results) > 2 {
+		return nil, fmt.Errorf("%s Handler must return (error) or (T, error), got %s", info.Name(), handlerType)
+	}
+
+	errResult := results[len(results)-1]
+	if !errResult.Type().Implements(errorInterfaceType) {
+		return nil, fmt.Errorf("%s Handler's last return value must be error, got %s", info.Name(), handlerType)
+	}
+	var err error
+	if !errResult.IsNil() {
+		err = errResult.Interface().(error)
+	}
+	if len(results) == 1 {
+		return nil, err
+	}
+	return results[0].Interface(), err
+}
+
+// eventFixture returns the payload used for the requested event type when a
+// local request has no body: the zero value of the matching
+// aws-lambda-go/events type, which carries no records or sample data.
+func eventFixture(eventType string) (interface{}, error) {
+	switch eventType {
+	case "s3":
+		return awsLambdaEvents.S3Event{}, nil
+	case "sns":
+		return awsLambdaEvents.SNSEvent{}, nil
+	case "dynamodb":
+		return awsLambdaEvents.DynamoDBEvent{}, nil
+	case "":
+		return json.RawMessage("{}"), nil
+	default:
+		return nil, fmt.Errorf("unknown event fixture type: %s", eventType)
+	}
+}
+
+// unmarshalTarget returns a fresh pointer of the Go type eventFixture
+// produces for eventType, suitable for json.Unmarshal.
+func unmarshalTarget(eventType string) interface{} {
+	switch eventType {
+	case "s3":
+		return &awsLambdaEvents.S3Event{}
+	case "sns":
+		return &awsLambdaEvents.SNSEvent{}
+	case "dynamodb":
+		return &awsLambdaEvents.DynamoDBEvent{}
+	default:
+		return &json.RawMessage{}
+	}
+}
+
+// inferredEventType returns the event fixture type implied by
+// lambdaInfo's registered Permissions/EventSourceMappings, so a developer
+// curling a Lambda doesn't have to specify it by hand for the common cases.
+func inferredEventType(lambdaInfo *LambdaAWSInfo) string {
+	for _, eachPermission := range lambdaInfo.Permissions {
+		switch eachPermission.(type) {
+		case S3Permission:
+			return "s3"
+		case SNSPermission:
+			return "sns"
+		}
+	}
+	if len(lambdaInfo.EventSourceMappings) != 0 {
+		return "dynamodb"
+	}
+	return ""
+}
+
+// lambdaHandlerMux routes a single POST /<LambdaName> request to the
+// in-process Go function registered for that name, unmarshalling the
+// request body into the typed input implied by the LambdaAWSInfo's
+// Permissions/EventSourceMappings.
+func lambdaHandlerMux(lambdaAWSInfos []*LambdaAWSInfo, logger *logrus.Logger) *http.ServeMux {
+	mux := http.NewServeMux()
+	for _, eachLambdaInfo := range lambdaAWSInfos {
+		lambdaInfo := eachLambdaInfo
+		mux.HandleFunc(fmt.Sprintf("/%s", lambdaInfo.lambdaFunctionName()), func(w http.ResponseWriter, r *http.Request) {
+			invokeLocalLambda(w, r, lambdaInfo, logger)
+		})
+	}
+	return mux
+}
+
+// invokeLocalLambda dispatches a single local HTTP request to the target
+// Lambda's handler and writes the result (or error) back as JSON. The event
+// type used to pick a typed input - and to synthesize a fixture payload
+// when the request body is empty - comes from the `?event=` query
+// parameter, falling back to inferredEventType(lambdaInfo).
+func invokeLocalLambda(w http.ResponseWriter, r *http.Request, lambdaInfo *LambdaAWSInfo, logger *logrus.Logger) {
+	eventType := r.URL.Query().Get("event")
+	if eventType == "" {
+		eventType = inferredEventType(lambdaInfo)
+	}
+
+	body, readErr := io.ReadAll(r.Body)
+	if readErr != nil {
+		http.Error(w, readErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(body) == 0 {
+		fixture, fixtureErr := eventFixture(eventType)
+		if fixtureErr != nil {
+			http.Error(w, fixtureErr.Error(), http.StatusBadRequest)
+			return
+		}
+		body, readErr = json.Marshal(fixture)
+		if readErr != nil {
+			http.Error(w, readErr.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	input := unmarshalTarget(eventType)
+	if unmarshalErr := json.Unmarshal(body, input); unmarshalErr != nil {
+		http.Error(w, unmarshalErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, invokeErr := lambdaInfo.invokeLocal(context.Background(), input)
+	if invokeErr != nil {
+		logger.WithFields(logrus.Fields{
+			"lambda": lambdaInfo.lambdaFunctionName(),
+			"error":  invokeErr,
+		}).Error("local invocation failed")
+		http.Error(w, invokeErr.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if encodeErr := json.NewEncoder(w).Encode(response); encodeErr != nil {
+		http.Error(w, encodeErr.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Execute starts an HTTP server that routes POST /<LambdaName> requests to
+// the corresponding in-process Go function, letting developers exercise the
+// full pipeline locally (eg `curl -d @event.json localhost:9999/mockLambda1`)
+// without deploying. If parentPID is non-zero, Execute signals it with
+// SIGUSR2 once the server is listening, which supervisor processes can use
+// to implement hot-reload workflows.
+func Execute(lambdaAWSInfos []*LambdaAWSInfo,
+	port int,
+	parentPID int,
+	logger *logrus.Logger) error {
+
+	mux := lambdaHandlerMux(lambdaAWSInfos, logger)
+	addr := fmt.Sprintf(":%d", port)
+	server := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
+	listener, listenErr := net.Listen("tcp", addr)
+	if listenErr != nil {
+		return fmt.Errorf("failed to listen on %s: %s", addr, listenErr)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"address": addr,
+	}).Info("local execution harness listening")
+
+	if parentPID != 0 {
+		parent, findErr := os.FindProcess(parentPID)
+		if findErr != nil {
+			return fmt.Errorf("failed to locate parent process %d: %s", parentPID, findErr)
+		}
+		if signalErr := parent.Signal(syscall.SIGUSR2); signalErr != nil {
+			logger.WithFields(logrus.Fields{
+				"parentPID": parentPID,
+				"error":     signalErr,
+			}).Warn("failed to signal parent process")
+		}
+	}
+
+	return server.Serve(listener)
+}